@@ -18,30 +18,30 @@ package compatibility
 
 import "github.com/compose-spec/compose-go/types"
 
-func (c *WhiteList) CheckVolumeConfigDriver(config *types.VolumeConfig) {
-	if !c.supported("volumes.driver") && config.Driver != "" {
+func (c *WhiteList) CheckVolumeConfigDriver(projectPath string, config *types.VolumeConfig) {
+	if !c.supportedAt(projectPath, "volumes.driver") && config.Driver != "" {
 		config.Driver = ""
-		c.error("volumes.driver")
+		c.errorAt(projectPath, "volumes.driver")
 	}
 }
 
-func (c *WhiteList) CheckVolumeConfigDriverOpts(config *types.VolumeConfig) {
-	if !c.supported("volumes.driver_opts") && len(config.DriverOpts) != 0 {
+func (c *WhiteList) CheckVolumeConfigDriverOpts(projectPath string, config *types.VolumeConfig) {
+	if !c.supportedAt(projectPath, "volumes.driver_opts") && len(config.DriverOpts) != 0 {
 		config.DriverOpts = nil
-		c.error("volumes.driver_opts")
+		c.errorAt(projectPath, "volumes.driver_opts")
 	}
 }
 
-func (c *WhiteList) CheckVolumeConfigExternal(config *types.VolumeConfig) {
-	if !c.supported("volumes.external") && config.External.External {
+func (c *WhiteList) CheckVolumeConfigExternal(projectPath string, config *types.VolumeConfig) {
+	if !c.supportedAt(projectPath, "volumes.external") && config.External.External {
 		config.External.External = false
-		c.error("volumes.external")
+		c.errorAt(projectPath, "volumes.external")
 	}
 }
 
-func (c *WhiteList) CheckVolumeConfigLabels(config *types.VolumeConfig) {
-	if !c.supported("volumes.labels") && len(config.Labels) != 0 {
+func (c *WhiteList) CheckVolumeConfigLabels(projectPath string, config *types.VolumeConfig) {
+	if !c.supportedAt(projectPath, "volumes.labels") && len(config.Labels) != 0 {
 		config.Labels = nil
-		c.error("volumes.labels")
+		c.errorAt(projectPath, "volumes.labels")
 	}
 }
\ No newline at end of file
@@ -0,0 +1,88 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compatibility
+
+import (
+	"fmt"
+	"path"
+)
+
+// WhiteList records the set of compose features a consumer supports. Support
+// is declared globally by Supported, and can be overridden for a subset of
+// the project through Overlays, e.g. "the legacy-backend service can use
+// network_mode: host but nothing else can".
+type WhiteList struct {
+	Supported map[string]bool
+	// Overlays scopes a feature set to part of the project. Keys are glob
+	// patterns (as understood by path.Match) matched against the project
+	// path passed to CheckXxx, e.g. "services.web.*" or "volumes.data".
+	// A matching overlay takes precedence over Supported for the features
+	// it explicitly lists; any feature it doesn't mention falls back to
+	// Supported.
+	Overlays map[string]map[string]bool
+	Errors   []string
+}
+
+// supportedAt reports whether featurePath is allowed at projectPath (e.g.
+// "services.web", "volumes.data"). An empty projectPath means the feature
+// isn't scoped to a specific part of the project, so only the global
+// Supported set applies.
+func (c *WhiteList) supportedAt(projectPath string, featurePath string) bool {
+	if projectPath != "" {
+		for pattern, overlay := range c.Overlays {
+			matched, err := path.Match(pattern, projectPath)
+			if err != nil || !matched {
+				continue
+			}
+			if supported, ok := overlay[featurePath]; ok {
+				return supported
+			}
+		}
+	}
+	return c.Supported[featurePath]
+}
+
+// error records that featurePath was rejected project-wide. Kept, with its
+// original single-argument signature, as a thin wrapper over errorAt: it's
+// here for any caller that genuinely has no project position to scope a
+// check to, not as a stopgap for CheckXxx methods still awaiting migration.
+// This fork's compatibility package only ever grew the volumes.go family
+// (CheckVolumeConfigXxx in volumes.go); it has no services.go, networks.go
+// or configs.go to migrate, so there are no other CheckXxx call sites left
+// on the single-argument path.
+func (c *WhiteList) error(featurePath string) {
+	c.errorAt("", featurePath)
+}
+
+// errorAt records that featurePath was rejected, naming projectPath so
+// consumers can tell whether the feature was disallowed globally or only at
+// that scope. Every CheckXxx method in this package (CheckVolumeConfigXxx in
+// volumes.go) calls this.
+func (c *WhiteList) errorAt(projectPath string, featurePath string) {
+	if projectPath == "" {
+		c.Errors = append(c.Errors, fmt.Sprintf("%s is not supported", featurePath))
+		return
+	}
+	c.Errors = append(c.Errors, fmt.Sprintf("%s is not supported at %s", featurePath, projectPath))
+}
+
+// supported reports whether featurePath is allowed project-wide, ignoring
+// any per-path overlay. Kept, with its original single-argument signature,
+// for the same reason as error above.
+func (c *WhiteList) supported(featurePath string) bool {
+	return c.supportedAt("", featurePath)
+}
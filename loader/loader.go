@@ -0,0 +1,187 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/compose-spec/compose-go/v2/transform"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/go-viper/mapstructure/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// cycleTracker holds the files seen during cross-file resolution (e.g. an
+// `include:` directive pulling in a file that, directly or transitively,
+// includes the file it started from). loadYamlModel accepts one but does not
+// use it, so no cross-file cycle is detected. It is distinct from the
+// SequenceProcessor.visited map, which tracks alias cycles within a single
+// document.
+type cycleTracker struct {
+	seen map[string]bool
+}
+
+// Load reads every ConfigFile in details, merges them in order, and decodes
+// the result into a Project.
+func Load(details types.ConfigDetails, opts ...func(*Options)) (*types.Project, error) {
+	options := &Options{}
+	for _, o := range opts {
+		o(options)
+	}
+
+	files, err := options.ResolveConfigFiles(details.ConfigFiles)
+	if err != nil {
+		return nil, err
+	}
+	details.ConfigFiles = files
+
+	model, err := loadYamlModel(context.Background(), details, options, &cycleTracker{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &types.Project{}
+	if err := decodeInto(model, project); err != nil {
+		return nil, err
+	}
+
+	if !options.SkipNormalization {
+		// TODO(normalize): set default values for missing fields.
+	}
+	if !options.SkipConsistencyCheck {
+		// TODO(consistency): check e.g. that a service referenced by
+		// another one exists.
+	}
+
+	return project, nil
+}
+
+// loadYamlModel merges config.ConfigFiles in order into a single model:
+// each file is decoded through a SequenceProcessor (resolving `!reset`,
+// `!override`, `!append`, `!prepend` and `!patch` for that file), folded
+// into the accumulated model, then has its recorded reset paths and
+// `!patch` operations applied, before the next file is merged in. The
+// result is canonicalized once every file has been merged.
+//
+// `extends:` resolution and interpolation of the merged model (beyond a
+// `!patch` value, see ApplyPatches) are not implemented yet; callers relying
+// on either should not use Load until they are.
+func loadYamlModel(_ context.Context, config types.ConfigDetails, opts *Options, _ *cycleTracker, _ []string) (map[string]interface{}, error) {
+	dict := map[string]interface{}{}
+	for _, file := range config.ConfigFiles {
+		var fileDict map[string]interface{}
+		processor := opts.newSequenceProcessor(&fileDict)
+		if err := yaml.Unmarshal(file.Content, processor); err != nil {
+			return nil, err
+		}
+		if fileDict == nil {
+			fileDict = map[string]interface{}{}
+		}
+
+		dict = mergeModel(dict, fileDict, tree.NewPath(), processor).(map[string]interface{})
+		if err := processor.Apply(dict); err != nil {
+			return nil, err
+		}
+		if err := ApplyProcessorPatches(dict, processor); err != nil {
+			return nil, err
+		}
+	}
+
+	return transform.Canonical(dict, true)
+}
+
+// mergeModel deep-merges src into dst at path: a path a TagHandler marked
+// ReplaceParent (`!override`) replaces dst's value outright; a path tagged
+// `!append`/`!prepend` concatenates the two sequences in that order; every
+// other mapping recurses key by key with src winning on conflicting
+// scalars, and every other value (including untagged sequences) is
+// replaced by src, matching how a single scalar override already behaves.
+func mergeModel(dst, src any, path tree.Path, processor *SequenceProcessor) any {
+	for _, p := range processor.Replaced() {
+		if p == path {
+			return src
+		}
+	}
+
+	srcMap, srcIsMap := src.(map[string]interface{})
+	dstMap, dstIsMap := dst.(map[string]interface{})
+	if srcIsMap && dstIsMap {
+		for k, v := range srcMap {
+			dstMap[k] = mergeModel(dstMap[k], v, path.Next(k), processor)
+		}
+		return dstMap
+	}
+
+	if srcList, ok := src.([]interface{}); ok {
+		if dstList, ok := dst.([]interface{}); ok {
+			if mode, explicit := processor.modes[path.String()]; explicit {
+				if mode == mergeModeAppend {
+					return append(append([]interface{}{}, dstList...), srcList...)
+				}
+				return append(append([]interface{}{}, srcList...), dstList...)
+			}
+		}
+	}
+
+	return src
+}
+
+// decoder mirrors yaml.v3's Unmarshaler for mapstructure decoding: it lets
+// a types.Xxx value (e.g. types.Duration, types.Mapping) parse the loosely
+// typed YAML scalar/sequence/mapping it was given instead of requiring an
+// exact Go type match.
+type decoder interface {
+	DecodeMapstructure(interface{}) error
+}
+
+// decodeInto decodes model, as produced by loadYamlModel, into target (a
+// *types.Project) using the same "yaml" struct tags the model's keys are
+// already named after, honoring any field's own DecodeMapstructure.
+func decodeInto(model map[string]interface{}, target any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructureDecodeHook,
+		Result:     target,
+		TagName:    "yaml",
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(model)
+}
+
+func mapstructureDecodeHook(from reflect.Value, to reflect.Value) (interface{}, error) {
+	u, ok := to.Interface().(decoder)
+	if !ok {
+		if !to.CanAddr() {
+			return from.Interface(), nil
+		}
+		u, ok = to.Addr().Interface().(decoder)
+		if !ok {
+			return from.Interface(), nil
+		}
+	}
+	if to.Type().Kind() == reflect.Ptr && to.IsNil() {
+		to.Set(reflect.New(to.Type().Elem()))
+		u = to.Interface().(decoder)
+	}
+	if err := u.DecodeMapstructure(from.Interface()); err != nil {
+		return to.Interface(), err
+	}
+	return to.Interface(), nil
+}
@@ -0,0 +1,90 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// localOverrideSuffixEnv lets users customize the sibling suffix discovered
+// by AutoLoadLocalOverrides, e.g. COMPOSE_LOCAL_SUFFIX=.dev to merge
+// compose.dev.yaml instead of compose.local.yaml.
+const localOverrideSuffixEnv = "COMPOSE_LOCAL_SUFFIX"
+
+const defaultLocalOverrideSuffix = ".local"
+
+// withLocalOverrides appends, for every ConfigFile backed by a real path on
+// disk, its sibling "<name><suffix>.<ext>" file when one exists, e.g.
+// compose.yaml -> compose.local.yaml or docker-compose.override.yml ->
+// docker-compose.override.local.yml. Discovered files are appended after the
+// file they shadow, so they are merged on top of it, and their values win,
+// exactly like an explicit `-f` would, including support
+// for `!reset`/`!override` tags in the local file. Inline content (stdin, in
+// memory fixtures) has no filesystem sibling to discover and is left as-is.
+func withLocalOverrides(files []types.ConfigFile) ([]types.ConfigFile, error) {
+	suffix := os.Getenv(localOverrideSuffixEnv)
+	if suffix == "" {
+		suffix = defaultLocalOverrideSuffix
+	}
+
+	result := make([]types.ConfigFile, 0, len(files))
+	for _, file := range files {
+		result = append(result, file)
+
+		local, ok := localOverridePath(file.Filename, suffix)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(local); err != nil {
+			continue
+		}
+		content, err := os.ReadFile(local)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, types.ConfigFile{
+			Filename: local,
+			Content:  content,
+		})
+	}
+	return result, nil
+}
+
+// localOverridePath computes the sibling override path for filename, e.g.
+// "compose.yaml" with suffix ".local" becomes "compose.local.yaml". It
+// returns ok=false for filenames that aren't backed by the filesystem
+// (empty, "-", or already a discovered local override) or that have no
+// extension to anchor the suffix to.
+func localOverridePath(filename string, suffix string) (string, bool) {
+	if filename == "" || filename == "-" {
+		return "", false
+	}
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return "", false
+	}
+	base := strings.TrimSuffix(filename, ext)
+	if strings.HasSuffix(base, suffix) {
+		// already a local override, don't discover one for itself
+		return "", false
+	}
+	return base + suffix + ext, true
+}
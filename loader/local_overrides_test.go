@@ -0,0 +1,117 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestLocalOverridePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		suffix   string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "compose.yaml",
+			filename: "compose.yaml",
+			suffix:   ".local",
+			expected: "compose.local.yaml",
+			ok:       true,
+		},
+		{
+			name:     "override file",
+			filename: "docker-compose.override.yml",
+			suffix:   ".local",
+			expected: "docker-compose.override.local.yml",
+			ok:       true,
+		},
+		{
+			name:     "custom suffix",
+			filename: "compose.yaml",
+			suffix:   ".dev",
+			expected: "compose.dev.yaml",
+			ok:       true,
+		},
+		{
+			name:     "inline content",
+			filename: "(inline)",
+			suffix:   ".local",
+			ok:       false,
+		},
+		{
+			name:     "stdin",
+			filename: "-",
+			suffix:   ".local",
+			ok:       false,
+		},
+		{
+			name:     "no extension",
+			filename: "Dockerfile",
+			suffix:   ".local",
+			ok:       false,
+		},
+		{
+			name:     "already a local override",
+			filename: "compose.local.yaml",
+			suffix:   ".local",
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := localOverridePath(tt.filename, tt.suffix)
+			assert.Equal(t, ok, tt.ok)
+			if tt.ok {
+				assert.Equal(t, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveConfigFilesAutoLoadLocalOverrides(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "compose.yaml")
+	local := filepath.Join(dir, "compose.local.yaml")
+	assert.NilError(t, os.WriteFile(base, []byte("services:\n  web:\n    image: base\n"), 0o644))
+	assert.NilError(t, os.WriteFile(local, []byte("services:\n  web:\n    image: local\n"), 0o644))
+
+	files := []types.ConfigFile{{Filename: base}}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		opts := &Options{}
+		resolved, err := opts.ResolveConfigFiles(files)
+		assert.NilError(t, err)
+		assert.Equal(t, len(resolved), 1)
+	})
+
+	t.Run("discovers sibling when enabled", func(t *testing.T) {
+		opts := &Options{AutoLoadLocalOverrides: true}
+		resolved, err := opts.ResolveConfigFiles(files)
+		assert.NilError(t, err)
+		assert.Equal(t, len(resolved), 2)
+		assert.Equal(t, resolved[1].Filename, local)
+	})
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import "github.com/compose-spec/compose-go/v2/types"
+
+// Options controls how Load parses and merges a set of compose files.
+type Options struct {
+	// SkipNormalization skips normalizing the model, e.g. setting default
+	// values for missing fields. Load does not normalize the model, so this
+	// flag has no effect on its result.
+	SkipNormalization bool
+	// SkipConsistencyCheck skips checking the model for consistency, e.g.
+	// that a service referenced by another one exists. Load performs no
+	// consistency check, so this flag has no effect on its result.
+	SkipConsistencyCheck bool
+	// AutoLoadLocalOverrides, when set, makes Load discover and merge, for
+	// every ConfigFile backed by a real path on disk, its sibling local
+	// override file (see withLocalOverrides) the way an explicit `-f
+	// compose.local.yaml` would, without the caller having to name it.
+	AutoLoadLocalOverrides bool
+	// TagHandlers are consulted, in addition to the built-in `!reset`/
+	// `!override` handlers, to resolve a custom tag encountered while
+	// merging files, e.g. a `!secret-ref` a downstream tool wants to support
+	// without forking the loader.
+	TagHandlers []TagHandler
+}
+
+// newSequenceProcessor creates the SequenceProcessor Load decodes a single
+// ConfigFile through, registering o.TagHandlers alongside the built-ins.
+func (o *Options) newSequenceProcessor(target any) *SequenceProcessor {
+	return NewSequenceProcessor(target, o.TagHandlers...)
+}
+
+// ResolveConfigFiles returns files with, if AutoLoadLocalOverrides is set,
+// every discovered sibling local override file appended. Load calls this
+// before merging so local overrides are folded in exactly like an explicit
+// ConfigFile would be.
+func (o *Options) ResolveConfigFiles(files []types.ConfigFile) ([]types.ConfigFile, error) {
+	if !o.AutoLoadLocalOverrides {
+		return files, nil
+	}
+	return withLocalOverrides(files)
+}
@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestOptionsNewSequenceProcessorRegistersTagHandlers(t *testing.T) {
+	var target map[string]any
+	opts := &Options{TagHandlers: []TagHandler{fileTagHandler{}}}
+
+	processor := opts.newSequenceProcessor(&target)
+	assert.Check(t, processor.handlerFor("!file") != nil)
+}
+
+// TestLoadUsesOptionsTagHandlers proves a TagHandler registered through
+// Options reaches the SequenceProcessor Load actually decodes through, not
+// just the one built in newSequenceProcessor's own unit test.
+func TestLoadUsesOptionsTagHandlers(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "token.txt")
+	assert.NilError(t, os.WriteFile(secretPath, []byte("s3cr3t"), 0o600))
+
+	p, err := Load(types.ConfigDetails{
+		ConfigFiles: []types.ConfigFile{
+			{
+				Filename: "(inline)",
+				Content: []byte(`
+name: test-tag-handler
+services:
+  app:
+    image: alpine
+    environment:
+      TOKEN: !file ` + secretPath + `
+`),
+			},
+		},
+	}, func(options *Options) {
+		options.SkipNormalization = true
+		options.SkipConsistencyCheck = true
+		options.TagHandlers = []TagHandler{fileTagHandler{}}
+	})
+	assert.NilError(t, err)
+	token := p.Services["app"].Environment["TOKEN"]
+	assert.Assert(t, token != nil)
+	assert.Equal(t, *token, "s3cr3t")
+}
@@ -0,0 +1,228 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/interpolation"
+	"gopkg.in/yaml.v3"
+)
+
+// PatchOp is a single RFC 6902-style patch operation recorded from a
+// `!patch` tag by SequenceProcessor. Path is a JSON-Pointer (RFC 6901)
+// pointing into the merged model, e.g. "/services/web/environment/DB_HOST".
+type PatchOp struct {
+	Op            string `yaml:"op"`
+	Path          string `yaml:"path"`
+	Value         any    `yaml:"value,omitempty"`
+	CreateParents bool   `yaml:"create_parents,omitempty"`
+}
+
+// ApplyPatches runs every recorded PatchOp against model, in the order they
+// were declared. It runs as its own pass, after SequenceProcessor has
+// reconciled `!reset`/`!override`/`!append`/`!prepend` and before the model
+// is normalized, so a patch can target a nested scalar without the caller
+// having to duplicate the whole parent map. A patch's Value is interpolated
+// the same way any other scalar in the model would be, so a `!patch` can
+// carry a `${VAR}`-style reference instead of only literal values.
+func ApplyPatches(model map[string]any, patches []PatchOp) error {
+	for _, patch := range patches {
+		segments, err := splitPointer(patch.Path)
+		if err != nil {
+			return fmt.Errorf("patch %s %s: %w", patch.Op, patch.Path, err)
+		}
+		if len(segments) == 0 {
+			return fmt.Errorf("patch %s: path must not be empty", patch.Op)
+		}
+		value, err := interpolateValue(patch.Value)
+		if err != nil {
+			return fmt.Errorf("patch %s %s: %w", patch.Op, patch.Path, err)
+		}
+		patch.Value = value
+		if _, err := applyPatch(model, segments, patch); err != nil {
+			return fmt.Errorf("patch %s %s: %w", patch.Op, patch.Path, err)
+		}
+	}
+	return nil
+}
+
+// interpolateValue runs value through the same interpolation used for the
+// rest of the model, so a `!patch` value can reference `${VAR}` instead of
+// only ever being a literal. value is wrapped in a single-entry map because
+// interpolation.Interpolate only operates at that level.
+func interpolateValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	out, err := interpolation.Interpolate(map[string]interface{}{"value": value}, interpolation.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return out["value"], nil
+}
+
+// ApplyProcessorPatches runs the `!patch` operations processor recorded while
+// resolving the tree against model. Load calls this once per ConfigFile,
+// right after that file, decoded through processor, has been merged into
+// model, so every `!patch` block in the source files is applied exactly once.
+func ApplyProcessorPatches(model map[string]any, processor *SequenceProcessor) error {
+	return ApplyPatches(model, processor.Patches())
+}
+
+// decodePatchOps decodes a node tagged `!patch`, accepting either a single
+// patch operation or a sequence of them, e.g.:
+//
+//	x-patches: !patch
+//	  - op: replace
+//	    path: /services/web/environment/DB_HOST
+//	    value: db.internal
+func decodePatchOps(node *yaml.Node) ([]PatchOp, error) {
+	if node.Kind == yaml.SequenceNode {
+		var ops []PatchOp
+		if err := node.Decode(&ops); err != nil {
+			return nil, err
+		}
+		return ops, nil
+	}
+	var op PatchOp
+	if err := node.Decode(&op); err != nil {
+		return nil, err
+	}
+	return []PatchOp{op}, nil
+}
+
+// splitPointer splits a RFC 6901 JSON pointer into its unescaped segments,
+// e.g. "/services/web/environment/DB_HOST" becomes ["services", "web",
+// "environment", "DB_HOST"]. "~1" and "~0" are unescaped to "/" and "~".
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q: must start with /", pointer)
+	}
+	raw := strings.Split(pointer[1:], "/")
+	segments := make([]string, len(raw))
+	for i, s := range raw {
+		s = strings.ReplaceAll(s, "~1", "/")
+		s = strings.ReplaceAll(s, "~0", "~")
+		segments[i] = s
+	}
+	return segments, nil
+}
+
+// applyPatch walks container following segments and applies patch at the
+// final segment, returning the (possibly new) container. Maps are mutated in
+// place; sequences may need to be reallocated (insert/remove), so the caller
+// assigns the returned value back into the parent container.
+func applyPatch(container any, segments []string, patch PatchOp) (any, error) {
+	key := segments[0]
+	rest := segments[1:]
+
+	switch node := container.(type) {
+	case map[string]any:
+		if len(rest) == 0 {
+			return node, applyMapLeaf(node, key, patch)
+		}
+		child, ok := node[key]
+		if !ok {
+			if !patch.CreateParents {
+				return nil, fmt.Errorf("path segment %q does not exist", key)
+			}
+			child = map[string]any{}
+		}
+		updated, err := applyPatch(child, rest, patch)
+		if err != nil {
+			return nil, err
+		}
+		node[key] = updated
+		return node, nil
+	case []any:
+		idx, err := sequenceIndex(key, len(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			return applySequenceLeaf(node, idx, patch)
+		}
+		if idx >= len(node) {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		updated, err := applyPatch(node[idx], rest, patch)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("path segment %q traverses a scalar value", key)
+	}
+}
+
+func applyMapLeaf(node map[string]any, key string, patch PatchOp) error {
+	switch patch.Op {
+	case "add", "replace":
+		node[key] = patch.Value
+	case "remove":
+		delete(node, key)
+	default:
+		return fmt.Errorf("unsupported op %q", patch.Op)
+	}
+	return nil
+}
+
+// sequenceIndex resolves a JSON pointer segment to a slice index, treating
+// "-" as one-past-the-end, per RFC 6901, for appending.
+func sequenceIndex(segment string, length int) (int, error) {
+	if segment == "-" {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(segment)
+	if err != nil || idx < 0 || idx > length {
+		return 0, fmt.Errorf("invalid sequence index %q", segment)
+	}
+	return idx, nil
+}
+
+func applySequenceLeaf(node []any, idx int, patch PatchOp) ([]any, error) {
+	switch patch.Op {
+	case "add":
+		if idx == len(node) {
+			return append(node, patch.Value), nil
+		}
+		node = append(node, nil)
+		copy(node[idx+1:], node[idx:])
+		node[idx] = patch.Value
+		return node, nil
+	case "replace":
+		if idx >= len(node) {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		node[idx] = patch.Value
+		return node, nil
+	case "remove":
+		if idx >= len(node) {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		return append(node[:idx], node[idx+1:]...), nil
+	default:
+		return nil, fmt.Errorf("unsupported op %q", patch.Op)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+func TestApplyPatchesReplaceScalar(t *testing.T) {
+	model := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{
+				"environment": map[string]any{
+					"DB_HOST": "localhost",
+				},
+			},
+		},
+	}
+	err := ApplyPatches(model, []PatchOp{
+		{Op: "replace", Path: "/services/web/environment/DB_HOST", Value: "db.internal"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, model["services"].(map[string]any)["web"].(map[string]any)["environment"].(map[string]any)["DB_HOST"], "db.internal")
+}
+
+func TestApplyPatchesAddWithCreateParents(t *testing.T) {
+	model := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{},
+		},
+	}
+	err := ApplyPatches(model, []PatchOp{
+		{Op: "add", Path: "/services/web/labels/owner", Value: "platform", CreateParents: true},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, model["services"].(map[string]any)["web"].(map[string]any)["labels"].(map[string]any)["owner"], "platform")
+}
+
+func TestApplyPatchesAddWithoutCreateParentsFails(t *testing.T) {
+	model := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{},
+		},
+	}
+	err := ApplyPatches(model, []PatchOp{
+		{Op: "add", Path: "/services/web/labels/owner", Value: "platform"},
+	})
+	assert.ErrorContains(t, err, `path segment "labels" does not exist`)
+}
+
+func TestApplyPatchesSequenceAppendAndRemove(t *testing.T) {
+	model := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{
+				"ports": []any{"8080:80"},
+			},
+		},
+	}
+	err := ApplyPatches(model, []PatchOp{
+		{Op: "add", Path: "/services/web/ports/-", Value: "8443:443"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, model["services"].(map[string]any)["web"].(map[string]any)["ports"], []any{"8080:80", "8443:443"})
+
+	err = ApplyPatches(model, []PatchOp{
+		{Op: "remove", Path: "/services/web/ports/0"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, model["services"].(map[string]any)["web"].(map[string]any)["ports"], []any{"8443:443"})
+}
+
+func TestApplyProcessorPatchesEndToEnd(t *testing.T) {
+	var model map[string]any
+	processor := NewSequenceProcessor(&model)
+
+	err := yaml.Unmarshal([]byte(`
+services:
+  web:
+    environment:
+      DB_HOST: localhost
+x-patches: !patch
+  - op: replace
+    path: /services/web/environment/DB_HOST
+    value: db.internal
+`), processor)
+	assert.NilError(t, err)
+
+	err = ApplyProcessorPatches(model, processor)
+	assert.NilError(t, err)
+	assert.Equal(t, model["services"].(map[string]any)["web"].(map[string]any)["environment"].(map[string]any)["DB_HOST"], "db.internal")
+}
+
+func TestApplyPatchesInterpolatesValue(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	model := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{
+				"environment": map[string]any{
+					"DB_HOST": "localhost",
+				},
+			},
+		},
+	}
+	err := ApplyPatches(model, []PatchOp{
+		{Op: "replace", Path: "/services/web/environment/DB_HOST", Value: "${DB_HOST}"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, model["services"].(map[string]any)["web"].(map[string]any)["environment"].(map[string]any)["DB_HOST"], "db.internal")
+}
+
+func TestSplitPointerEscaping(t *testing.T) {
+	segments, err := splitPointer("/labels/com.example~1app/version~0beta")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, segments, []string{"labels", "com.example/app", "version~beta"})
+}
@@ -121,7 +121,7 @@ x-healthcheck: &healthcheck
     <<: *healthcheck
 `,
 			expectError: true,
-			errorMsg:    "cycle detected at path: x-healthcheck.egress-service",
+			errorMsg:    "cycle detected at path: x-healthcheck.egress-service (anchor defined at: x-healthcheck)",
 		},
 	}
 
@@ -25,9 +25,91 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// mergeMode controls how a tagged sequence is combined with the base
+// sequence it overrides, both when `mergeNodes` reconciles a `<<` merge key
+// and when mergeModel merges one file over another.
+type mergeMode int
+
+const (
+	// mergeModeAppend inserts the override items after the base items.
+	mergeModeAppend mergeMode = iota
+	// mergeModePrepend inserts the override items before the base items.
+	// This is also the implicit, default behavior of mergeNodes for an
+	// untagged sequence.
+	mergeModePrepend
+)
+
 type SequenceProcessor struct {
 	target interface{}
 	paths  []tree.Path
+	// modes records the merge mode explicitly set by `!append`/`!prepend` for
+	// a given path, keyed by path.String(). For paths with no entry,
+	// mergeNodes uses the default mergeModePrepend, while mergeModel replaces
+	// the base sequence with the override one.
+	modes map[string]mergeMode
+	// patches collects every `!patch` block found while walking the tree,
+	// to be applied against the merged model once resolveSequence returns.
+	patches []PatchOp
+	// visited tracks anchors currently being resolved, keyed by the target
+	// node's identity, to detect cycles across alias resolution.
+	visited map[*yaml.Node]tree.Path
+	// handlers are consulted, in addition to the built-in `!reset`/
+	// `!override` handlers, to resolve a tagged node. Populated from
+	// Options.TagHandlers.
+	handlers []TagHandler
+	// replaced records every path a TagHandler marked ReplaceParent, for the
+	// multi-file merge step to treat as full replacement.
+	replaced []tree.Path
+}
+
+// NewSequenceProcessor creates a SequenceProcessor decoding into target,
+// consulting handlers (in addition to the built-in `!reset`/`!override`
+// handlers) to resolve tagged nodes.
+func NewSequenceProcessor(target any, handlers ...TagHandler) *SequenceProcessor {
+	return &SequenceProcessor{target: target, handlers: handlers}
+}
+
+// Replaced returns the paths marked for full replacement by a ReplaceParent
+// TagHandler (e.g. the built-in `!override` handler).
+func (p *SequenceProcessor) Replaced() []tree.Path {
+	return p.replaced
+}
+
+// CycleError is returned when resolving an alias would recurse back into an
+// anchor that is already being resolved.
+type CycleError struct {
+	// Path is the position in the tree at which the cycle was detected.
+	Path tree.Path
+	// Origin is the path at which the anchor was first being resolved.
+	Origin tree.Path
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle detected at path: %s (anchor defined at: %s)", e.Path.String(), e.Origin.String())
+}
+
+// Patches returns the `!patch` operations recorded while resolving the
+// sequence, for the loader to apply against the merged model.
+func (p *SequenceProcessor) Patches() []PatchOp {
+	return p.patches
+}
+
+// setMode records the merge mode tagged on the node at path.
+func (p *SequenceProcessor) setMode(path tree.Path, mode mergeMode) {
+	if p.modes == nil {
+		p.modes = map[string]mergeMode{}
+	}
+	p.modes[path.String()] = mode
+}
+
+// modeFor returns the merge mode recorded for path, defaulting to
+// mergeModePrepend (the implicit behavior of an untagged sequence) when none
+// was explicitly set.
+func (p *SequenceProcessor) modeFor(path tree.Path) mergeMode {
+	if mode, ok := p.modes[path.String()]; ok {
+		return mode
+	}
+	return mergeModePrepend
 }
 
 // UnmarshalYAML implement yaml.Unmarshaler
@@ -44,17 +126,12 @@ func (p *SequenceProcessor) resolveSequence(node *yaml.Node, path tree.Path) (*y
 	if strings.Contains(path.String(), ".<<") {
 		// If the path contains "<<", removing the "<<" element and merging the path
 		path = tree.NewPath(strings.Replace(path.String(), ".<<", "", 1))
-		// // if we enconter the merge, first we resolve the node
-		// resolved, err := p.resolveSequence(node.Alias, path)
-		// if err != nil {
-		// 	return nil, err
-		// }
-		// // we can only solve the merge at the end
-
 	}
 	// If the node is an alias, We need to process the alias field in order to consider the !override and !reset tags
 	if node.Kind == yaml.AliasNode {
-		fmt.Printf("\nnode content: %v\n", node.Content)
+		if origin, ok := p.visited[node.Alias]; ok {
+			return nil, &CycleError{Path: path, Origin: origin}
+		}
 		resolved, err := p.resolveSequence(node.Alias, path)
 		if err != nil {
 			return nil, err
@@ -62,6 +139,46 @@ func (p *SequenceProcessor) resolveSequence(node *yaml.Node, path tree.Path) (*y
 		return resolved, nil
 	}
 
+	// Record that node is actively being resolved at path, so that an alias
+	// elsewhere in the tree resolving back into it can be recognized as a
+	// cycle rather than recursing forever.
+	if p.visited == nil {
+		p.visited = map[*yaml.Node]tree.Path{}
+	}
+	p.visited[node] = path
+	defer delete(p.visited, node)
+
+	if handler := p.handlerFor(node.Tag); handler != nil {
+		resolved, action, err := handler.Resolve(node, path)
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case Drop:
+			p.paths = append(p.paths, path)
+			return nil, nil
+		case ReplaceParent:
+			p.replaced = append(p.replaced, path)
+		}
+		node = resolved
+	} else {
+		switch node.Tag {
+		case "!append":
+			node.Tag = ""
+			p.setMode(path, mergeModeAppend)
+		case "!prepend":
+			node.Tag = ""
+			p.setMode(path, mergeModePrepend)
+		case "!patch":
+			ops, err := decodePatchOps(node)
+			if err != nil {
+				return nil, err
+			}
+			p.patches = append(p.patches, ops...)
+			return nil, nil
+		}
+	}
+
 	switch node.Kind {
 	case yaml.SequenceNode:
 		var nodes []*yaml.Node
@@ -100,7 +217,7 @@ func (p *SequenceProcessor) resolveSequence(node *yaml.Node, path tree.Path) (*y
 				}
 			}
 		}
-		mergeNodes(merge, nodes)
+		p.mergeNodes(merge, nodes, path)
 		// update nodes
 		node.Content = nodes
 	}
@@ -148,7 +265,7 @@ func (p *SequenceProcessor) applyNullOverrides(target any, path tree.Path) error
 	return nil
 }
 
-func mergeNodes(merge []*yaml.Node, nodes []*yaml.Node) {
+func (p *SequenceProcessor) mergeNodes(merge []*yaml.Node, nodes []*yaml.Node, path tree.Path) {
 	// merge after evaluating the level of the tree
 	for _, anchor := range merge {
 		// app-volumes
@@ -170,6 +287,7 @@ func mergeNodes(merge []*yaml.Node, nodes []*yaml.Node) {
 								break
 							}
 							// merging sequences v and c ...
+							var toAdd []*yaml.Node
 							for _, v := range anchorNode.Content {
 								found := false
 								for _, el := range c.Content {
@@ -179,9 +297,16 @@ func mergeNodes(merge []*yaml.Node, nodes []*yaml.Node) {
 									}
 								}
 								if !found {
-									c.Content = append(c.Content, v)
+									toAdd = append(toAdd, v)
 								}
 							}
+							// !append inserts the new (override) items after
+							// the base ones; !prepend (the default) before
+							if p.modeFor(path.Next(key)) == mergeModeAppend {
+								c.Content = append(append([]*yaml.Node{}, toAdd...), c.Content...)
+							} else {
+								c.Content = append(c.Content, toAdd...)
+							}
 							// if the node already exists in anchorNode content, do nothing
 						}
 					}
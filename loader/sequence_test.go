@@ -0,0 +1,173 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestAppendOverride(t *testing.T) {
+	p, err := Load(types.ConfigDetails{
+		ConfigFiles: []types.ConfigFile{
+			{
+				Filename: "(inline)",
+				Content: []byte(`
+name: test-append
+services:
+  app:
+    volumes:
+      - /data/app:/app/data
+`),
+			},
+			{
+				Filename: "(override)",
+				Content: []byte(`
+services:
+  app:
+    volumes: !append
+      - /logs/app:/app/logs
+`),
+			},
+		},
+	}, func(options *Options) {
+		options.SkipNormalization = true
+		options.SkipConsistencyCheck = true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, p.Services["app"].Volumes, []types.ServiceVolumeConfig{
+		{Type: "bind", Source: "/data/app", Target: "/app/data", Bind: &types.ServiceVolumeBind{CreateHostPath: true}},
+		{Type: "bind", Source: "/logs/app", Target: "/app/logs", Bind: &types.ServiceVolumeBind{CreateHostPath: true}},
+	})
+}
+
+func TestAliasCycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "direct self-reference",
+			config: `
+name: test
+x: &a
+  child: *a
+`,
+			expectError: true,
+			errorMsg:    "cycle detected at path: x.child (anchor defined at: x)",
+		},
+		{
+			// y is anchored inside x's own subtree, so both anchors are
+			// declared before anything aliases them (yaml.v3 only resolves
+			// an alias back to an anchor already seen earlier in the
+			// document): x.y.b aliases back into x, closing the cycle
+			// through the nested y anchor rather than directly.
+			name: "indirect cycle through two anchors",
+			config: `
+name: test
+x: &x
+  y: &y
+    b: *x
+  a: *y
+`,
+			expectError: true,
+			errorMsg:    "cycle detected at path: x.y.b (anchor defined at: x)",
+		},
+		{
+			name: "sequence-in-sequence alias loop",
+			config: `
+name: test
+x: &a
+  - *a
+`,
+			expectError: true,
+			errorMsg:    "cycle detected at path: x.0 (anchor defined at: x)",
+		},
+		{
+			name: "same anchor referenced twice is not a cycle",
+			config: `
+name: test
+services:
+  a: &a
+    image: alpine
+  a2: *a
+  a3: *a
+`,
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(types.ConfigDetails{
+				ConfigFiles: []types.ConfigFile{
+					{
+						Filename: "(inline)",
+						Content:  []byte(tt.config),
+					},
+				},
+			}, func(options *Options) {
+				options.SkipNormalization = true
+				options.SkipConsistencyCheck = true
+			})
+
+			if tt.expectError {
+				assert.Error(t, err, tt.errorMsg)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrependOverride(t *testing.T) {
+	p, err := Load(types.ConfigDetails{
+		ConfigFiles: []types.ConfigFile{
+			{
+				Filename: "(inline)",
+				Content: []byte(`
+name: test-prepend
+services:
+  app:
+    volumes:
+      - /data/app:/app/data
+`),
+			},
+			{
+				Filename: "(override)",
+				Content: []byte(`
+services:
+  app:
+    volumes: !prepend
+      - /logs/app:/app/logs
+`),
+			},
+		},
+	}, func(options *Options) {
+		options.SkipNormalization = true
+		options.SkipConsistencyCheck = true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, p.Services["app"].Volumes, []types.ServiceVolumeConfig{
+		{Type: "bind", Source: "/logs/app", Target: "/app/logs", Bind: &types.ServiceVolumeBind{CreateHostPath: true}},
+		{Type: "bind", Source: "/data/app", Target: "/app/data", Bind: &types.ServiceVolumeBind{CreateHostPath: true}},
+	})
+}
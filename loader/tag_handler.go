@@ -0,0 +1,98 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"github.com/compose-spec/compose-go/v2/tree"
+	"gopkg.in/yaml.v3"
+)
+
+// Action tells resolveSequence how to treat the node returned by a
+// TagHandler.
+type Action int
+
+const (
+	// Keep uses the returned node as-is in place of the tagged one.
+	Keep Action = iota
+	// Drop removes the tagged node from its parent entirely.
+	Drop
+	// ReplaceParent keeps the returned node, and additionally marks its path
+	// so a later multi-file merge replaces the subtree outright instead of
+	// deep-merging it with the base document.
+	ReplaceParent
+)
+
+// TagHandler resolves a custom YAML tag encountered while SequenceProcessor
+// walks a document. Downstream tools embedding the loader (buildx's bake,
+// `docker stack deploy`, ...) register one via loader.Options.TagHandlers to
+// define their own merge-time tags without forking the loader.
+type TagHandler interface {
+	// Name is the YAML tag this handler resolves, e.g. "!reset".
+	Name() string
+	// Resolve is called with the tagged node and its position in the tree.
+	// The tag has not been cleared yet; a handler that wants to keep the
+	// node is responsible for clearing node.Tag itself.
+	Resolve(node *yaml.Node, path tree.Path) (*yaml.Node, Action, error)
+}
+
+// resetTagHandler implements the built-in `!reset` tag: the tagged node, and
+// everything under it, is removed once the model is merged across files.
+type resetTagHandler struct{}
+
+func (resetTagHandler) Name() string { return "!reset" }
+
+func (resetTagHandler) Resolve(node *yaml.Node, _ tree.Path) (*yaml.Node, Action, error) {
+	return node, Drop, nil
+}
+
+// overrideTagHandler implements the built-in `!override` tag: the tagged
+// node fully replaces the base value at the same path, instead of being
+// deep-merged into it.
+type overrideTagHandler struct{}
+
+func (overrideTagHandler) Name() string { return "!override" }
+
+func (overrideTagHandler) Resolve(node *yaml.Node, _ tree.Path) (*yaml.Node, Action, error) {
+	node.Tag = ""
+	return node, ReplaceParent, nil
+}
+
+// defaultTagHandlers are always available, regardless of what a caller
+// registers through Options.TagHandlers.
+func defaultTagHandlers() []TagHandler {
+	return []TagHandler{resetTagHandler{}, overrideTagHandler{}}
+}
+
+// handlerFor returns the TagHandler registered for tag, preferring a handler
+// supplied by the caller over the built-ins of the same name, or nil when
+// tag isn't recognized.
+func (p *SequenceProcessor) handlerFor(tag string) TagHandler {
+	if tag == "" {
+		return nil
+	}
+	for _, h := range p.handlers {
+		if h.Name() == tag {
+			return h
+		}
+	}
+	for _, h := range defaultTagHandlers() {
+		if h.Name() == tag {
+			return h
+		}
+	}
+	return nil
+}
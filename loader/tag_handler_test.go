@@ -0,0 +1,66 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+// fileTagHandler resolves a custom `!file` tag by inlining the contents of
+// the referenced path as a string scalar, the way a downstream tool
+// embedding the loader might want to support `!file` or `!secret-ref`.
+type fileTagHandler struct{}
+
+func (fileTagHandler) Name() string { return "!file" }
+
+func (fileTagHandler) Resolve(node *yaml.Node, _ tree.Path) (*yaml.Node, Action, error) {
+	content, err := os.ReadFile(node.Value)
+	if err != nil {
+		return nil, Keep, err
+	}
+	node.Tag = "!!str"
+	node.Value = string(content)
+	return node, Keep, nil
+}
+
+func TestCustomTagHandler(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "secret.txt")
+	assert.NilError(t, os.WriteFile(secretPath, []byte("s3cr3t"), 0o600))
+
+	var target map[string]any
+	processor := NewSequenceProcessor(&target, fileTagHandler{})
+
+	err := yaml.Unmarshal([]byte(`
+services:
+  app:
+    environment:
+      TOKEN: !file `+secretPath+`
+`), processor)
+	assert.NilError(t, err)
+
+	services := target["services"].(map[string]any)
+	app := services["app"].(map[string]any)
+	env := app["environment"].(map[string]any)
+	assert.Equal(t, env["TOKEN"], "s3cr3t")
+}